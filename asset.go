@@ -0,0 +1,83 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// AssetInfo is what a fingerprinted static asset resolves to: its
+// content-hashed URL, and a subresource-integrity digest for it.
+type AssetInfo struct {
+	URL       string // fingerprinted absolute-path URL, e.g. "/css/style.a1b2c3d4.css"
+	Integrity string // "sha384-<base64>", for a <link>/<script> integrity attribute
+}
+
+// AssetManifest maps an asset's site-relative input path (e.g.
+// "css/style.css") to where it ended up after fingerprinting.
+type AssetManifest map[string]AssetInfo
+
+// copyAssetFingerprinted copies a non-markdown input file to outputDir,
+// inserting an 8-character content hash into its filename
+// (style.css -> style.a1b2c3d4.css) so it can be served with long-lived
+// immutable caching, and records the result in assets so page templates
+// can resolve it via {{ asset "css/style.css" }}.
+func copyAssetFingerprinted(inputPath, inputDir, outputDir string, assets AssetManifest) error {
+	content, err := os.ReadFile(inputPath)
+	if err != nil {
+		return err
+	}
+
+	relPath, err := filepath.Rel(inputDir, inputPath)
+	if err != nil {
+		return err
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	sum256 := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum256[:])[:8]
+
+	ext := filepath.Ext(relPath)
+	hashedRelPath := strings.TrimSuffix(relPath, ext) + "." + hash + ext
+
+	outputPath := filepath.Join(outputDir, filepath.FromSlash(hashedRelPath))
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(outputPath, content, 0o644); err != nil {
+		return err
+	}
+
+	sum384 := sha512.Sum384(content)
+	assets[relPath] = AssetInfo{
+		URL:       "/" + hashedRelPath,
+		Integrity: "sha384-" + base64.StdEncoding.EncodeToString(sum384[:]),
+	}
+	return nil
+}
+
+// assetFuncMap returns the template functions that resolve a
+// fingerprinted asset's URL and integrity digest:
+//
+//	<link rel="stylesheet" href="{{ asset "css/style.css" }}"
+//	      integrity="{{ assetIntegrity "css/style.css" }}" crossorigin="anonymous">
+func assetFuncMap(assets AssetManifest) template.FuncMap {
+	return template.FuncMap{
+		"asset": func(path string) string {
+			if info, ok := assets[path]; ok {
+				return info.URL
+			}
+			fmt.Printf("%s No fingerprinted asset found for %q\n", yellow("Warning:"), path)
+			return "/" + path
+		},
+		"assetIntegrity": func(path string) string {
+			return assets[path].Integrity
+		},
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestCopyAssetFingerprinted(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	cssPath := filepath.Join(inputDir, "css", "style.css")
+	if err := os.MkdirAll(filepath.Dir(cssPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(cssPath, []byte("body { color: red; }"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	assets := make(AssetManifest)
+	if err := copyAssetFingerprinted(cssPath, inputDir, outputDir, assets); err != nil {
+		t.Fatalf("copyAssetFingerprinted() error = %s", err)
+	}
+
+	info, ok := assets["css/style.css"]
+	if !ok {
+		t.Fatalf("expected assets[%q] to be recorded, got %v", "css/style.css", assets)
+	}
+
+	wantPattern := regexp.MustCompile(`^/css/style\.[0-9a-f]{8}\.css$`)
+	if !wantPattern.MatchString(info.URL) {
+		t.Errorf("URL = %q, want to match %s", info.URL, wantPattern)
+	}
+	if info.Integrity == "" || info.Integrity[:7] != "sha384-" {
+		t.Errorf("Integrity = %q, want sha384- prefix", info.Integrity)
+	}
+
+	outputPath := filepath.Join(outputDir, filepath.FromSlash(info.URL[1:]))
+	if _, err := os.Stat(outputPath); err != nil {
+		t.Errorf("expected fingerprinted file at %s: %s", outputPath, err)
+	}
+}
+
+func TestCopyAssetFingerprintedChangesHashWhenContentChanges(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	cssPath := filepath.Join(inputDir, "style.css")
+	if err := os.WriteFile(cssPath, []byte("body { color: red; }"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	assets := make(AssetManifest)
+	if err := copyAssetFingerprinted(cssPath, inputDir, outputDir, assets); err != nil {
+		t.Fatal(err)
+	}
+	firstURL := assets["style.css"].URL
+
+	if err := os.WriteFile(cssPath, []byte("body { color: blue; }"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := copyAssetFingerprinted(cssPath, inputDir, outputDir, assets); err != nil {
+		t.Fatal(err)
+	}
+	secondURL := assets["style.css"].URL
+
+	if firstURL == secondURL {
+		t.Errorf("expected hashed URL to change after content changed, both = %q", firstURL)
+	}
+}
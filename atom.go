@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// atomFeed and atomEntry model the subset of RFC 4287 goss needs.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	ID      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Link    atomLink `xml:"link"`
+	Summary string   `xml:"summary,omitempty"`
+}
+
+// parseFrontMatterDate parses the handful of date layouts front matter
+// authors tend to use. It reports false if the date is empty or
+// unrecognized.
+func parseFrontMatterDate(date string) (time.Time, bool) {
+	if date == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// generateAtomFeed writes feed.xml for every page under config.FeedDir,
+// newest first.
+func generateAtomFeed(pages []Page, config Configuration) error {
+	if config.BaseURL == "" {
+		fmt.Printf("%s BaseURL not set, skipping feed.xml\n", yellow("Warning:"))
+		return nil
+	}
+
+	var posts []Page
+	for _, page := range pages {
+		if strings.HasPrefix(page.URL, config.FeedDir) {
+			posts = append(posts, page)
+		}
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		return posts[i].FrontMatter.Date.After(posts[j].FrontMatter.Date)
+	})
+
+	feed := atomFeed{
+		Xmlns:   "http://www.w3.org/2005/Atom",
+		Title:   "Feed",
+		ID:      config.BaseURL + "/",
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Link:    atomLink{Href: config.BaseURL + "/feed.xml", Rel: "self"},
+	}
+
+	for _, page := range posts {
+		updated := time.Now().UTC()
+		if !page.FrontMatter.Date.IsZero() {
+			updated = page.FrontMatter.Date
+		}
+
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   page.FrontMatter.Title,
+			ID:      config.BaseURL + page.URL,
+			Updated: updated.UTC().Format(time.RFC3339),
+			Link:    atomLink{Href: config.BaseURL + page.URL},
+			Summary: page.FrontMatter.Description,
+		})
+	}
+
+	out, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append([]byte(xml.Header), out...)
+
+	return os.WriteFile(filepath.Join(config.OutputDir, "feed.xml"), out, 0o644)
+}
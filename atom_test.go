@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFrontMatterDate(t *testing.T) {
+	tests := []struct {
+		name string
+		date string
+		want time.Time
+		ok   bool
+	}{
+		{
+			name: "empty string",
+			date: "",
+			ok:   false,
+		},
+		{
+			name: "RFC3339",
+			date: "2024-03-05T10:30:00Z",
+			want: time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC),
+			ok:   true,
+		},
+		{
+			name: "date and time without timezone",
+			date: "2024-03-05T10:30:00",
+			want: time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC),
+			ok:   true,
+		},
+		{
+			name: "date and time with space separator",
+			date: "2024-03-05 10:30:00",
+			want: time.Date(2024, 3, 5, 10, 30, 0, 0, time.UTC),
+			ok:   true,
+		},
+		{
+			name: "date only",
+			date: "2024-03-05",
+			want: time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+			ok:   true,
+		},
+		{
+			name: "unrecognized layout",
+			date: "March 5th, 2024",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseFrontMatterDate(tt.date)
+			if ok != tt.ok {
+				t.Fatalf("parseFrontMatterDate(%q) ok = %v, want %v", tt.date, ok, tt.ok)
+			}
+			if ok && !got.Equal(tt.want) {
+				t.Errorf("parseFrontMatterDate(%q) = %v, want %v", tt.date, got, tt.want)
+			}
+		})
+	}
+}
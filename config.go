@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// SiteConfig holds site-wide settings loaded from goss.yaml/goss.toml, as
+// opposed to the per-invocation flags in Configuration.
+type SiteConfig struct {
+	// Taxonomies lists custom taxonomies beyond the built-in "tags",
+	// e.g. "categories". Terms are read from the matching front matter
+	// field of the same name.
+	Taxonomies []string `yaml:"taxonomies,omitempty" toml:"taxonomies,omitempty"`
+
+	// Highlight configures chroma syntax highlighting of fenced code
+	// blocks.
+	Highlight HighlightConfig `yaml:"highlight,omitempty" toml:"highlight,omitempty"`
+}
+
+// HighlightConfig configures chroma syntax highlighting for fenced code
+// blocks.
+type HighlightConfig struct {
+	// Style is a chroma style name (e.g. "github", "monokai"). Defaults
+	// to "github".
+	Style string `yaml:"style,omitempty" toml:"style,omitempty"`
+
+	// LineNumbers shows line numbers alongside highlighted code.
+	LineNumbers bool `yaml:"line_numbers,omitempty" toml:"line_numbers,omitempty"`
+
+	// LineAnchorPrefix, when set, gives each line number an "id"
+	// attribute of "<prefix><line>" so individual lines can be linked
+	// to. Only takes effect when LineNumbers is set.
+	LineAnchorPrefix string `yaml:"line_anchor_prefix,omitempty" toml:"line_anchor_prefix,omitempty"`
+
+	// CSSClasses emits CSS classes instead of inline styles, writing the
+	// corresponding stylesheet to chroma.css in the output directory
+	// once per build. Defaults to false (inline styles).
+	CSSClasses bool `yaml:"css_classes,omitempty" toml:"css_classes,omitempty"`
+}
+
+// loadSiteConfig reads SiteConfig from path, as YAML or TOML depending on
+// its extension. A missing file is not an error; goss.yaml/goss.toml is
+// optional.
+func loadSiteConfig(path string) (SiteConfig, error) {
+	config := SiteConfig{Highlight: HighlightConfig{Style: defaultHighlightStyle}}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+	if err != nil {
+		return config, err
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".toml") {
+		if err := toml.Unmarshal(data, &config); err != nil {
+			return config, err
+		}
+		return config, nil
+	}
+
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
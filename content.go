@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Site is the in-memory model of every page in the build, exposed to
+// templates as .Site so a page can link to or list its siblings.
+type Site struct {
+	Pages       []Page
+	Collections map[string][]Page            // top-level input directory -> its pages
+	Taxonomies  map[string]map[string][]Page // taxonomy name -> term -> pages
+}
+
+// buildSite indexes pages into collections (by top-level input
+// directory) and taxonomies (front matter Tags, plus any custom
+// taxonomies declared in goss.yaml).
+func buildSite(pages []Page, siteConfig SiteConfig) Site {
+	site := Site{
+		Pages:       pages,
+		Collections: make(map[string][]Page),
+		Taxonomies:  map[string]map[string][]Page{"tags": {}},
+	}
+	for _, name := range siteConfig.Taxonomies {
+		site.Taxonomies[name] = make(map[string][]Page)
+	}
+
+	for _, page := range pages {
+		if page.Collection != "" {
+			site.Collections[page.Collection] = append(site.Collections[page.Collection], page)
+		}
+
+		for _, tag := range page.FrontMatter.Tags {
+			site.Taxonomies["tags"][tag] = append(site.Taxonomies["tags"][tag], page)
+		}
+
+		for _, name := range siteConfig.Taxonomies {
+			for _, term := range customTaxonomyTerms(page.FrontMatter, name) {
+				site.Taxonomies[name][term] = append(site.Taxonomies[name][term], page)
+			}
+		}
+	}
+
+	return site
+}
+
+// customTaxonomyTerms reads the terms for a custom taxonomy out of a
+// page's front matter, accepting either a single string or a list.
+func customTaxonomyTerms(frontMatter FrontMatter, taxonomy string) []string {
+	raw, ok := frontMatter.Custom[taxonomy]
+	if !ok {
+		return nil
+	}
+
+	switch v := raw.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		terms := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				terms = append(terms, s)
+			}
+		}
+		return terms
+	default:
+		return nil
+	}
+}
+
+// deriveCollection returns the top-level input directory a markdown
+// file lives under (e.g. "blog" for "blog/2024/post.md"), or "" for
+// pages at the root of the input directory.
+func deriveCollection(inputPath, inputDir string) string {
+	relPath, err := filepath.Rel(inputDir, inputPath)
+	if err != nil {
+		return ""
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	if idx := strings.Index(relPath, "/"); idx != -1 {
+		return relPath[:idx]
+	}
+	return ""
+}
+
+// generateListPages auto-generates an index page for every collection
+// and taxonomy term (e.g. /posts/index.html, /tags/golang/index.html)
+// using the user-supplied templates/list.html. It's a no-op if no
+// list.html template exists.
+func generateListPages(site Site, config Configuration, assets AssetManifest) error {
+	listTemplatePath := filepath.Join(config.TemplatesDir, "list.html")
+	if _, err := os.Stat(listTemplatePath); os.IsNotExist(err) {
+		fmt.Printf("%s No list.html template found, skipping collection and taxonomy list pages\n", yellow("Notice:"))
+		return nil
+	}
+
+	for collection, pages := range site.Collections {
+		outputPath := filepath.Join(config.OutputDir, collection, "index.html")
+		if err := renderListPage(listTemplatePath, collection, pages, site, assets, outputPath); err != nil {
+			return fmt.Errorf("collection %q: %w", collection, err)
+		}
+	}
+
+	for taxonomy, terms := range site.Taxonomies {
+		for term, pages := range terms {
+			outputPath := filepath.Join(config.OutputDir, taxonomy, term, "index.html")
+			if err := renderListPage(listTemplatePath, term, pages, site, assets, outputPath); err != nil {
+				return fmt.Errorf("taxonomy %s/%s: %w", taxonomy, term, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// renderSinglePage loads and renders one markdown file against an
+// existing set of pages, keeping .Site.* consistent for incremental dev
+// server rebuilds without re-walking the whole input tree.
+func renderSinglePage(inputPath string, existingPages []Page, siteConfig SiteConfig, config Configuration, assets AssetManifest) (*Page, *BuildError) {
+	page, buildErr := loadPage(inputPath, config.InputDir, siteConfig.Highlight)
+	if buildErr != nil {
+		return nil, buildErr
+	}
+
+	merged := make([]Page, 0, len(existingPages)+1)
+	replaced := false
+	for _, p := range existingPages {
+		if p.InputPath == inputPath {
+			merged = append(merged, *page)
+			replaced = true
+		} else {
+			merged = append(merged, p)
+		}
+	}
+	if !replaced {
+		merged = append(merged, *page)
+	}
+
+	relPath, err := filepath.Rel(config.InputDir, inputPath)
+	if err != nil {
+		buildErr := newBuildError(page.URL, inputPath, "", err)
+		return page, &buildErr
+	}
+	outputPath := filepath.Join(config.OutputDir, relPath)
+
+	site := buildSite(merged, siteConfig)
+	if buildErr := renderPage(page, config.TemplatesDir, outputPath, site, assets); buildErr != nil {
+		return page, buildErr
+	}
+	return page, nil
+}
+
+// renderListPage executes list.html with the given title and pages and
+// writes the result to outputPath.
+func renderListPage(templatePath, title string, pages []Page, site Site, assets AssetManifest, outputPath string) error {
+	tmpl, err := template.New(filepath.Base(templatePath)).Funcs(assetFuncMap(assets)).ParseFiles(templatePath)
+	if err != nil {
+		return err
+	}
+
+	var output bytes.Buffer
+	data := map[string]interface{}{
+		"Title": title,
+		"Pages": pages,
+		"Site":  site,
+	}
+	if err := tmpl.Execute(&output, data); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(outputPath, output.Bytes(), 0o644)
+}
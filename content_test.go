@@ -0,0 +1,65 @@
+package main
+
+import "testing"
+
+func TestCustomTaxonomyTerms(t *testing.T) {
+	tests := []struct {
+		name     string
+		custom   map[string]interface{}
+		taxonomy string
+		want     []string
+	}{
+		{
+			name:     "single string term",
+			custom:   map[string]interface{}{"series": "golang-internals"},
+			taxonomy: "series",
+			want:     []string{"golang-internals"},
+		},
+		{
+			name:     "list of string terms",
+			custom:   map[string]interface{}{"series": []interface{}{"a", "b"}},
+			taxonomy: "series",
+			want:     []string{"a", "b"},
+		},
+		{
+			name:     "taxonomy not present in front matter",
+			custom:   map[string]interface{}{"other": "value"},
+			taxonomy: "series",
+			want:     nil,
+		},
+		{
+			name:     "non-string list entries are skipped",
+			custom:   map[string]interface{}{"series": []interface{}{"a", 2, "c"}},
+			taxonomy: "series",
+			want:     []string{"a", "c"},
+		},
+		{
+			name:     "unsupported value type yields no terms",
+			custom:   map[string]interface{}{"series": 42},
+			taxonomy: "series",
+			want:     nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm := FrontMatter{Custom: tt.custom}
+			got := customTaxonomyTerms(fm, tt.taxonomy)
+			if !equalStringSlices(got, tt.want) {
+				t.Errorf("customTaxonomyTerms(%v, %q) = %v, want %v", tt.custom, tt.taxonomy, got, tt.want)
+			}
+		})
+	}
+}
+
+func equalStringSlices(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
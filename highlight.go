@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+)
+
+// defaultHighlightStyle is used when goss.yaml/goss.toml doesn't set
+// highlight.style.
+const defaultHighlightStyle = "github"
+
+// newHighlightingExtension builds the goldmark extension that
+// syntax-highlights fenced code blocks with chroma, honoring the style,
+// line number, and CSS-mode settings from goss.yaml/goss.toml. Per-block
+// overrides (```go {hl_lines=["2-4"]}) are handled by the extension
+// itself from the fenced code info string.
+func newHighlightingExtension(highlight HighlightConfig) goldmark.Extender {
+	style := highlight.Style
+	if style == "" {
+		style = defaultHighlightStyle
+	}
+
+	formatOptions := []chromahtml.Option{chromahtml.WithClasses(highlight.CSSClasses)}
+	if highlight.LineNumbers {
+		formatOptions = append(formatOptions, chromahtml.WithLineNumbers(true))
+		if highlight.LineAnchorPrefix != "" {
+			formatOptions = append(formatOptions, chromahtml.LinkableLineNumbers(true, highlight.LineAnchorPrefix))
+		}
+	}
+
+	return highlighting.NewHighlighting(
+		highlighting.WithStyle(style),
+		highlighting.WithFormatOptions(formatOptions...),
+	)
+}
+
+// writeChromaCSS writes the stylesheet for highlight's style to
+// chroma.css in outputDir. Only needed in CSS-classes mode; inline-style
+// mode (the default) needs no separate stylesheet.
+func writeChromaCSS(highlight HighlightConfig, outputDir string) error {
+	style := styles.Get(highlight.Style)
+	if style == nil {
+		style = styles.Get(defaultHighlightStyle)
+	}
+
+	f, err := os.Create(filepath.Join(outputDir, "chroma.css"))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	formatter := chromahtml.New(chromahtml.WithClasses(true))
+	return formatter.WriteCSS(f, style)
+}
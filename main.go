@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"html/template"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,12 +20,18 @@ import (
 
 // Configuration holds the command line arguments
 type Configuration struct {
-	InputDir     string
-	OutputDir    string
-	TemplatesDir string
-	Serve        bool
-	Host         string
-	Port         int
+	InputDir            string
+	OutputDir           string
+	TemplatesDir        string
+	Serve               bool
+	Host                string
+	Port                int
+	BaseURL             string
+	FeedDir             string
+	DisableBrowserError bool
+	ConfigPath          string
+	IncludeDrafts       bool
+	IncludeFuture       bool
 }
 
 // FrontMatter represents the YAML front matter in markdown files
@@ -34,11 +39,27 @@ type FrontMatter struct {
 	Title       string                 `yaml:"title,omitempty"`
 	Template    string                 `yaml:"template,omitempty"`
 	Description string                 `yaml:"description,omitempty"`
-	Date        string                 `yaml:"date,omitempty"`
+	DateString  string                 `yaml:"date,omitempty"` // raw "date:" value, in whichever layout the author used
+	Date        time.Time              `yaml:"-"`              // parsed from DateString by parseFrontMatter
+	Draft       bool                   `yaml:"draft,omitempty"`
 	Tags        []string               `yaml:"tags,omitempty"`
+	Sitemap     *bool                  `yaml:"sitemap,omitempty"`
+	Priority    string                 `yaml:"priority,omitempty"`
+	Changefreq  string                 `yaml:"changefreq,omitempty"`
 	Custom      map[string]interface{} `yaml:",inline"`
 }
 
+// Page represents a single rendered page, kept in memory so later build
+// steps (sitemap, feeds, collections, taxonomies) don't need to re-walk
+// the input tree.
+type Page struct {
+	InputPath   string
+	URL         string // absolute-path URL relative to BaseURL, e.g. "/blog/post-1/"
+	Collection  string // top-level input directory the page lives under, e.g. "blog"; empty for root-level pages
+	FrontMatter FrontMatter
+	Content     template.HTML // rendered markdown, before it's dropped into a page template
+}
+
 // Colors for console output
 var (
 	blue    = color.New(color.FgBlue).SprintFunc()
@@ -69,21 +90,33 @@ func parseFlags() Configuration {
 	serve := flag.Bool("s", false, "Start development server after build")
 	host := flag.String("host", "0.0.0.0", "Host address to bind development server")
 	port := flag.Int("port", 8000, "Port for development server")
+	baseURL := flag.String("baseurl", "", "Site base URL, used to produce absolute URLs in sitemap.xml and feed.xml")
+	feedDir := flag.String("feed-dir", "/blog/", "Directory (URL path prefix) of posts to include in feed.xml")
+	disableBrowserError := flag.Bool("disable-browser-error", false, "Disable the in-browser build error overlay in serve mode, falling back to stderr-only errors")
+	configPath := flag.String("config", "goss.yaml", "Path to the site configuration file")
+	drafts := flag.Bool("drafts", false, "Include pages marked draft: true in the build")
+	future := flag.Bool("future", false, "Include pages dated in the future in the build")
 
 	flag.Parse()
 
 	return Configuration{
-		InputDir:     *inputDir,
-		OutputDir:    *outputDir,
-		TemplatesDir: *templatesDir,
-		Serve:        *serve,
-		Host:         *host,
-		Port:         *port,
+		InputDir:            *inputDir,
+		OutputDir:           *outputDir,
+		TemplatesDir:        *templatesDir,
+		Serve:               *serve,
+		Host:                *host,
+		Port:                *port,
+		BaseURL:             strings.TrimSuffix(*baseURL, "/"),
+		FeedDir:             *feedDir,
+		DisableBrowserError: *disableBrowserError,
+		ConfigPath:          *configPath,
+		IncludeDrafts:       *drafts,
+		IncludeFuture:       *future,
 	}
 }
 
 // build processes the input directory and generates the static site
-func build(config Configuration) {
+func build(config Configuration) ([]Page, []BuildError, AssetManifest) {
 	fmt.Println(blue("Build Configuration:"))
 	fmt.Printf("%s %s\n", yellow("Input directory:"), config.InputDir)
 	fmt.Printf("%s %s\n", yellow("Output directory:"), config.OutputDir)
@@ -92,11 +125,11 @@ func build(config Configuration) {
 	// Check if directories exist
 	if _, err := os.Stat(config.InputDir); os.IsNotExist(err) {
 		fmt.Printf("%s Input directory does not exist: %s\n", red("Error:"), config.InputDir)
-		return
+		return nil, nil, nil
 	}
 	if _, err := os.Stat(config.TemplatesDir); os.IsNotExist(err) {
 		fmt.Printf("%s Templates directory does not exist: %s\n", red("Error:"), config.TemplatesDir)
-		return
+		return nil, nil, nil
 	}
 
 	// List all files in input directory
@@ -113,7 +146,13 @@ func build(config Configuration) {
 	})
 	if err != nil {
 		fmt.Printf("%s Error walking input directory: %s\n", red("Error:"), err)
-		return
+		return nil, nil, nil
+	}
+
+	// Load site-wide settings (collections/taxonomies config, etc.)
+	siteConfig, err := loadSiteConfig(config.ConfigPath)
+	if err != nil {
+		fmt.Printf("%s Error loading %s: %s\n", red("Error:"), config.ConfigPath, err)
 	}
 
 	// Start with a clean output directory
@@ -124,7 +163,17 @@ func build(config Configuration) {
 	start := time.Now()
 	count := 0
 
-	// Process all files in input directory
+	// In-memory model of every page, shared by the sitemap, feed, and
+	// collection/taxonomy list generators below so we don't have to
+	// re-walk the input tree.
+	var pages []Page
+	var buildErrors []BuildError
+	assets := make(AssetManifest)
+
+	// First pass: read front matter and convert markdown to HTML for
+	// every page, and fingerprint-copy other assets. Page templates
+	// aren't rendered yet, since they need the full Site model (and the
+	// full asset manifest) built below.
 	err = filepath.Walk(config.InputDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return err
@@ -137,13 +186,23 @@ func build(config Configuration) {
 			// Create directory structure in output
 			os.MkdirAll(outputPath, 0o755)
 		} else if isMarkdownFile(path) {
-			// Convert markdown files to HTML
-			renderMarkdown(path, outputPath, config.TemplatesDir)
+			page, buildErr := loadPage(path, config.InputDir, siteConfig.Highlight)
+			if page != nil {
+				if isPublished(*page, config) {
+					pages = append(pages, *page)
+				} else {
+					fmt.Printf("%s %s\n", yellow("Skipping unpublished:"), path)
+				}
+			}
+			if buildErr != nil {
+				buildErrors = append(buildErrors, *buildErr)
+			}
 			count++
 		} else if !strings.HasPrefix(filepath.Base(path), ".") { // Skip hidden files
-			// Copy all other files as-is
-			os.MkdirAll(filepath.Dir(outputPath), 0o755)
-			copyFile(path, outputPath)
+			// Fingerprint and copy all other files (CSS, JS, images, ...)
+			if err := copyAssetFingerprinted(path, config.InputDir, config.OutputDir, assets); err != nil {
+				fmt.Printf("%s Error copying %s: %s\n", red("Error:"), path, err)
+			}
 			count++
 		}
 
@@ -151,15 +210,65 @@ func build(config Configuration) {
 	})
 	if err != nil {
 		fmt.Printf("%s Error processing files: %s\n", red("Error:"), err)
-		return
+		return nil, nil, nil
+	}
+
+	// Index every page into collections and taxonomies before rendering
+	// any of them, so each page's own template can see .Site.
+	site := buildSite(pages, siteConfig)
+
+	// Second pass: render each page's template now that Site is known.
+	for i := range pages {
+		relPath, _ := filepath.Rel(config.InputDir, pages[i].InputPath)
+		outputPath := filepath.Join(config.OutputDir, relPath)
+
+		if buildErr := renderPage(&pages[i], config.TemplatesDir, outputPath, site, assets); buildErr != nil {
+			buildErrors = append(buildErrors, *buildErr)
+		}
 	}
 
 	// Handle robots.txt after processing other files
 	handleRobotsTxt(config.InputDir, config.OutputDir)
 
+	// Generate sitemap.xml and feed.xml off the in-memory page model
+	if err := generateSitemap(pages, config); err != nil {
+		fmt.Printf("%s Error generating sitemap.xml: %s\n", red("Error:"), err)
+	}
+	if err := generateAtomFeed(pages, config); err != nil {
+		fmt.Printf("%s Error generating feed.xml: %s\n", red("Error:"), err)
+	}
+
+	// Auto-generate collection and taxonomy list pages (/posts/,
+	// /tags/golang/, ...) off the same Site model.
+	if err := generateListPages(site, config, assets); err != nil {
+		fmt.Printf("%s Error generating list pages: %s\n", red("Error:"), err)
+	}
+
+	// In CSS-classes mode, write the chroma stylesheet once per build.
+	if siteConfig.Highlight.CSSClasses {
+		if err := writeChromaCSS(siteConfig.Highlight, config.OutputDir); err != nil {
+			fmt.Printf("%s Error writing chroma.css: %s\n", red("Error:"), err)
+		}
+	}
+
 	// Log build completion statistics
 	elapsed := time.Since(start).Seconds()
 	fmt.Printf("%s Processed %d files in %.2f seconds.\n", green("✓"), count, elapsed)
+
+	return pages, buildErrors, assets
+}
+
+// isPublished reports whether a page belongs in the build, given
+// config.IncludeDrafts/IncludeFuture. Pages with draft: true are excluded
+// by default, as are pages dated after the current time.
+func isPublished(page Page, config Configuration) bool {
+	if page.FrontMatter.Draft && !config.IncludeDrafts {
+		return false
+	}
+	if !config.IncludeFuture && page.FrontMatter.Date.After(time.Now()) {
+		return false
+	}
+	return true
 }
 
 // isMarkdownFile checks if a file is a markdown file
@@ -211,16 +320,22 @@ func parseFrontMatter(content string) (FrontMatter, string) {
 		fmt.Printf("%s Error parsing front matter: %s\n", red("Error:"), err)
 	}
 
+	if t, ok := parseFrontMatterDate(frontMatter.DateString); ok {
+		frontMatter.Date = t
+	}
+
 	return frontMatter, markdownContent
 }
 
-// renderMarkdown converts a markdown file to HTML using template
-func renderMarkdown(inputPath, outputPath, templatesDir string) {
-	// Read markdown file
+// loadPage reads a markdown file, parses its front matter, and converts
+// its body to HTML. It does not render the page's template yet — that
+// happens in renderPage, once the full Site model is known.
+func loadPage(inputPath, inputDir string, highlight HighlightConfig) (*Page, *BuildError) {
 	content, err := os.ReadFile(inputPath)
 	if err != nil {
 		fmt.Printf("%s Error reading markdown file: %s\n", red("Error:"), err)
-		return
+		buildErr := newBuildError(derivePageURL(inputPath, inputDir), inputPath, "", err)
+		return nil, &buildErr
 	}
 
 	// Parse front matter and content
@@ -228,7 +343,7 @@ func renderMarkdown(inputPath, outputPath, templatesDir string) {
 
 	// Convert markdown to HTML using goldmark
 	md := goldmark.New(
-		goldmark.WithExtensions(extension.GFM, extension.Typographer),
+		goldmark.WithExtensions(extension.GFM, extension.Typographer, newHighlightingExtension(highlight)),
 		goldmark.WithParserOptions(
 			parser.WithAutoHeadingID(),
 		),
@@ -236,14 +351,31 @@ func renderMarkdown(inputPath, outputPath, templatesDir string) {
 	var buf bytes.Buffer
 	if err := md.Convert([]byte(markdownContent), &buf); err != nil {
 		fmt.Printf("%s Error converting markdown: %s\n", red("Error:"), err)
-		return
+		buildErr := newBuildError(derivePageURL(inputPath, inputDir), inputPath, markdownContent, err)
+		return nil, &buildErr
 	}
-	htmlContent := buf.String()
 
 	fmt.Printf("%s %s\n", blue("Processing"), inputPath)
 	fmt.Printf("%s %s\n", green("Template:"), frontMatter.Template)
 	fmt.Printf("%s %d\n", yellow("Content length:"), len(markdownContent))
+	if frontMatter.Draft {
+		fmt.Printf("%s %s\n", magenta("Draft:"), inputPath)
+	}
 
+	return &Page{
+		InputPath:   inputPath,
+		URL:         derivePageURL(inputPath, inputDir),
+		Collection:  deriveCollection(inputPath, inputDir),
+		FrontMatter: frontMatter,
+		Content:     template.HTML(buf.String()), // Mark as safe HTML
+	}, nil
+}
+
+// renderPage executes a page's template (default.html, or whatever its
+// front matter names) and writes the result to outputPath. On failure it
+// returns a BuildError describing what went wrong and where, for the dev
+// server's error overlay.
+func renderPage(page *Page, templatesDir, outputPath string, site Site, assets AssetManifest) *BuildError {
 	// Get list of available templates for logging
 	var templates []string
 	filepath.Walk(templatesDir, func(path string, info os.FileInfo, err error) error {
@@ -257,31 +389,34 @@ func renderMarkdown(inputPath, outputPath, templatesDir string) {
 
 	// Prepare data for template
 	templateData := map[string]interface{}{
-		"Title":       frontMatter.Title,
-		"Description": frontMatter.Description,
-		"Date":        frontMatter.Date,
-		"Content":     template.HTML(htmlContent), // Mark as safe HTML
+		"Title":       page.FrontMatter.Title,
+		"Description": page.FrontMatter.Description,
+		"Date":        page.FrontMatter.Date,
+		"Content":     page.Content,
+		"Site":        site,
 	}
 
 	// Add any custom fields from front matter
-	for k, v := range frontMatter.Custom {
+	for k, v := range page.FrontMatter.Custom {
 		templateData[k] = v
 	}
 
 	// Load template
-	templateFile := frontMatter.Template
+	templateFile := page.FrontMatter.Template
 	if templateFile == "" {
 		templateFile = "default.html"
 	}
 
 	tmplPath := filepath.Join(templatesDir, templateFile)
-	tmpl, err := template.ParseFiles(tmplPath)
+	tmplSource, _ := os.ReadFile(tmplPath)
+	tmpl, err := template.New(filepath.Base(tmplPath)).Funcs(assetFuncMap(assets)).ParseFiles(tmplPath)
 	if err != nil {
 		fmt.Printf("%s Error loading template %s: %s\n", red("Error:"), templateFile, err)
 		// Fall back to direct HTML output
-		htmlOutput := fmt.Sprintf("<html><body>%s</body></html>", htmlContent)
-		writeHTMLFile(outputPath, inputPath, []byte(htmlOutput))
-		return
+		htmlOutput := fmt.Sprintf("<html><body>%s</body></html>", page.Content)
+		writeHTMLFile(outputPath, page.InputPath, []byte(htmlOutput))
+		buildErr := newBuildError(page.URL, tmplPath, string(tmplSource), err)
+		return &buildErr
 	}
 
 	// Render template
@@ -289,26 +424,33 @@ func renderMarkdown(inputPath, outputPath, templatesDir string) {
 	if err := tmpl.Execute(&output, templateData); err != nil {
 		fmt.Printf("%s Error executing template: %s\n", red("Error:"), err)
 		// Fall back to direct HTML output
-		htmlOutput := fmt.Sprintf("<html><body>%s</body></html>", htmlContent)
-		writeHTMLFile(outputPath, inputPath, []byte(htmlOutput))
-		return
+		htmlOutput := fmt.Sprintf("<html><body>%s</body></html>", page.Content)
+		writeHTMLFile(outputPath, page.InputPath, []byte(htmlOutput))
+		buildErr := newBuildError(page.URL, tmplPath, string(tmplSource), err)
+		return &buildErr
 	}
 
-	writeHTMLFile(outputPath, inputPath, output.Bytes())
+	writeHTMLFile(outputPath, page.InputPath, output.Bytes())
+	return nil
 }
 
-// writeHTMLFile determines the output path and writes HTML content
-func writeHTMLFile(outputPath, inputPath string, content []byte) {
-	// Determine output path
-	outputHTMLPath := outputPath
+// derivePageOutputPath applies writeHTMLFile's layout rules (index.md
+// keeps its directory; anything else gets its own directory with
+// index.html inside) without touching the filesystem, so callers like
+// the dev server's deleted-file cleanup can find a page's output file
+// without having rendered it.
+func derivePageOutputPath(outputPath, inputPath string) string {
 	if filepath.Base(inputPath) == "index.md" {
-		// For index.md files, keep the same directory structure
-		outputHTMLPath = strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".html"
-	} else {
-		// For other files, create a directory and place index.html inside
-		baseName := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
-		outputHTMLPath = filepath.Join(filepath.Dir(outputPath), baseName, "index.html")
+		return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".html"
 	}
+	baseName := strings.TrimSuffix(filepath.Base(inputPath), filepath.Ext(inputPath))
+	return filepath.Join(filepath.Dir(outputPath), baseName, "index.html")
+}
+
+// writeHTMLFile determines the output path and writes HTML content,
+// returning the path it was written to.
+func writeHTMLFile(outputPath, inputPath string, content []byte) string {
+	outputHTMLPath := derivePageOutputPath(outputPath, inputPath)
 
 	// Ensure output directory exists
 	os.MkdirAll(filepath.Dir(outputHTMLPath), 0o755)
@@ -320,6 +462,8 @@ func writeHTMLFile(outputPath, inputPath string, content []byte) {
 	if err != nil {
 		fmt.Printf("%s Error writing HTML file: %s\n", red("Error:"), err)
 	}
+
+	return outputHTMLPath
 }
 
 // handleRobotsTxt handles the robots.txt file for the site
@@ -345,175 +489,3 @@ Sitemap: sitemap.xml`
 		}
 	}
 }
-
-// serve starts a development server and watches for file changes
-func serve(config Configuration) {
-	// Build site initially
-	build(config)
-
-	// Setup HTTP server for serving files
-	http.Handle("/", http.FileServer(http.Dir(config.OutputDir)))
-
-	serverAddr := fmt.Sprintf("%s:%d", config.Host, config.Port)
-	fmt.Printf("\n%s http://%s\n", green("Starting server at"), serverAddr)
-	fmt.Printf("%s\n", yellow("Press Ctrl+C to quit"))
-	fmt.Printf("%s\n", blue("Watching for changes in:"))
-	fmt.Printf("%s %s\n", blue("- Input:"), config.InputDir)
-	fmt.Printf("%s %s\n", blue("- Templates:"), config.TemplatesDir)
-
-	// Start HTTP server in a goroutine
-	go func() {
-		if err := http.ListenAndServe(serverAddr, logRequest(http.DefaultServeMux)); err != nil {
-			fmt.Printf("%s Server error: %s\n", red("Error:"), err)
-			os.Exit(1)
-		}
-	}()
-
-	// Create channel for clean termination
-	done := make(chan bool)
-
-	// Store file modification times
-	lastModified := make(map[string]time.Time)
-	lastRebuild := time.Now()
-
-	// Initialize the lastModified map with current file information
-	initializeFileMap := func(dir string) {
-		filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				return err
-			}
-			if !info.IsDir() {
-				lastModified[path] = info.ModTime()
-			}
-			return nil
-		})
-	}
-
-	// Initialize with current file state
-	initializeFileMap(config.InputDir)
-	initializeFileMap(config.TemplatesDir)
-
-	// Start file change detection goroutine
-	go func() {
-		ticker := time.NewTicker(1 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ticker.C:
-				filesChanged := false
-				changedSource := ""
-				changedFile := ""
-
-				// Check for changes in input directory
-				err := filepath.Walk(config.InputDir, func(path string, info os.FileInfo, err error) error {
-					if err != nil {
-						return err
-					}
-
-					// Skip hidden files and directories
-					if strings.HasPrefix(filepath.Base(path), ".") {
-						if info.IsDir() {
-							return filepath.SkipDir
-						}
-						return nil
-					}
-
-					// Skip temporary files
-					if strings.HasSuffix(path, ".tmp") {
-						return nil
-					}
-
-					// Check if file is new or modified
-					if !info.IsDir() {
-						modTime := info.ModTime()
-						lastMod, exists := lastModified[path]
-
-						if !exists || modTime.After(lastMod) {
-							filesChanged = true
-							changedSource = "input files"
-							changedFile = path
-							lastModified[path] = modTime
-						}
-					}
-					return nil
-				})
-				if err != nil {
-					fmt.Printf("%s Error checking for file changes: %s\n", red("Error:"), err)
-				}
-
-				// Check for changes in templates directory
-				if !filesChanged {
-					err := filepath.Walk(config.TemplatesDir, func(path string, info os.FileInfo, err error) error {
-						if err != nil {
-							return err
-						}
-
-						// Skip hidden files and directories
-						if strings.HasPrefix(filepath.Base(path), ".") {
-							if info.IsDir() {
-								return filepath.SkipDir
-							}
-							return nil
-						}
-
-						// Check if file is new or modified
-						if !info.IsDir() {
-							modTime := info.ModTime()
-							lastMod, exists := lastModified[path]
-
-							if !exists || modTime.After(lastMod) {
-								filesChanged = true
-								changedSource = "template files"
-								changedFile = path
-								lastModified[path] = modTime
-							}
-						}
-						return nil
-					})
-					if err != nil {
-						fmt.Printf("%s Error checking for file changes: %s\n", red("Error:"), err)
-					}
-				}
-
-				// If files changed and enough time has passed since the last rebuild, trigger a rebuild
-				if filesChanged && time.Since(lastRebuild).Seconds() >= 1 {
-					fmt.Printf("\n%s %s\n", yellow("Changes detected in "+changedSource+":"), changedFile)
-					fmt.Printf("%s\n", cyan("Rebuilding entire site..."))
-
-					build(config)
-					fmt.Printf("%s\n", green("Rebuild complete!"))
-
-					lastRebuild = time.Now()
-
-					// Update the file map after rebuild
-					initializeFileMap(config.InputDir)
-					initializeFileMap(config.TemplatesDir)
-				}
-			case <-done:
-				return
-			}
-		}
-	}()
-
-	<-done // Block forever
-}
-
-// logRequest wraps an http.Handler with request logging
-func logRequest(handler http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		start := time.Now()
-
-		// Call the original handler
-		handler.ServeHTTP(w, r)
-
-		// Log the request with colors
-		fmt.Printf("%s %s %s %s %s\n",
-			cyan(r.RemoteAddr),
-			time.Now().Format("02/Jan/2006:15:04:05 -0700"),
-			magenta(r.URL.Path),
-			r.Method,
-			yellow(time.Since(start).String()),
-		)
-	})
-}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsPublished(t *testing.T) {
+	past := time.Now().Add(-24 * time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+
+	tests := []struct {
+		name   string
+		page   Page
+		config Configuration
+		want   bool
+	}{
+		{
+			name: "ordinary past-dated page is published",
+			page: Page{FrontMatter: FrontMatter{Date: past}},
+			want: true,
+		},
+		{
+			name: "draft excluded by default",
+			page: Page{FrontMatter: FrontMatter{Draft: true, Date: past}},
+			want: false,
+		},
+		{
+			name:   "draft included with IncludeDrafts",
+			page:   Page{FrontMatter: FrontMatter{Draft: true, Date: past}},
+			config: Configuration{IncludeDrafts: true},
+			want:   true,
+		},
+		{
+			name: "future-dated page excluded by default",
+			page: Page{FrontMatter: FrontMatter{Date: future}},
+			want: false,
+		},
+		{
+			name:   "future-dated page included with IncludeFuture",
+			page:   Page{FrontMatter: FrontMatter{Date: future}},
+			config: Configuration{IncludeFuture: true},
+			want:   true,
+		},
+		{
+			name: "zero date is treated as published",
+			page: Page{FrontMatter: FrontMatter{}},
+			want: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPublished(tt.page, tt.config); got != tt.want {
+				t.Errorf("isPublished() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDerivePageOutputPath(t *testing.T) {
+	tests := []struct {
+		name       string
+		outputPath string
+		inputPath  string
+		want       string
+	}{
+		{
+			name:       "index.md keeps its directory",
+			outputPath: "output/blog/index.md",
+			inputPath:  "input/blog/index.md",
+			want:       "output/blog/index.html",
+		},
+		{
+			name:       "other files get their own directory with index.html",
+			outputPath: "output/blog/post1.md",
+			inputPath:  "input/blog/post1.md",
+			want:       "output/blog/post1/index.html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := derivePageOutputPath(tt.outputPath, tt.inputPath); got != tt.want {
+				t.Errorf("derivePageOutputPath(%q, %q) = %q, want %q", tt.outputPath, tt.inputPath, got, tt.want)
+			}
+		})
+	}
+}
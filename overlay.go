@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BuildError captures a markdown/template failure well enough to route a
+// dev server request to it and show a developer what went wrong and
+// where, rather than a bare stack trace on stderr.
+type BuildError struct {
+	URL     string // route this error should be served for, e.g. "/blog/post-1/"
+	Path    string // source file that failed to render
+	Line    int    // 1-indexed; 0 if unknown
+	Column  int    // 1-indexed; 0 if unknown
+	Message string
+	Snippet string // a few lines of source around Line; empty if unknown
+}
+
+// templateErrLocationRe pulls "line:col" or "line" out of the
+// "template: name:line:col: message" / "template: name:line: message"
+// errors text/template and html/template return.
+var templateErrLocationRe = regexp.MustCompile(`:(\d+)(?::(\d+))?:`)
+
+// newBuildError builds a BuildError from a goldmark or text/template
+// failure. url is the page route the overlay should be served for; path
+// is the file the error actually points at (the markdown file itself, or
+// the template it uses). source, when available, is used to attach a
+// snippet of surrounding context around the error's line.
+func newBuildError(url, path, source string, err error) BuildError {
+	buildErr := BuildError{
+		URL:     url,
+		Path:    path,
+		Message: err.Error(),
+	}
+
+	if m := templateErrLocationRe.FindStringSubmatch(err.Error()); m != nil {
+		buildErr.Line, _ = strconv.Atoi(m[1])
+		if m[2] != "" {
+			buildErr.Column, _ = strconv.Atoi(m[2])
+		}
+	}
+
+	if buildErr.Line > 0 && source != "" {
+		buildErr.Snippet = sourceSnippet(source, buildErr.Line, 3)
+	}
+
+	return buildErr
+}
+
+// sourceSnippet returns up to `context` lines of src on either side of
+// line (1-indexed), each prefixed with its line number.
+func sourceSnippet(src string, line, context int) string {
+	lines := strings.Split(src, "\n")
+
+	start := line - 1 - context
+	if start < 0 {
+		start = 0
+	}
+	end := line - 1 + context
+	if end >= len(lines) {
+		end = len(lines) - 1
+	}
+
+	var b strings.Builder
+	for i := start; i <= end; i++ {
+		marker := "  "
+		if i == line-1 {
+			marker = "> "
+		}
+		fmt.Fprintf(&b, "%s%4d | %s\n", marker, i+1, lines[i])
+	}
+	return b.String()
+}
+
+// derivePageURL predicts the site URL a markdown file will render to,
+// mirroring writeHTMLFile's layout rules, without touching the
+// filesystem. This lets even a failed render be routed to the right
+// error overlay.
+func derivePageURL(inputPath, inputDir string) string {
+	relPath, err := filepath.Rel(inputDir, inputPath)
+	if err != nil {
+		return ""
+	}
+	relPath = filepath.ToSlash(relPath)
+
+	dir := ""
+	if i := strings.LastIndex(relPath, "/"); i != -1 {
+		dir = relPath[:i+1]
+	}
+	base := strings.TrimSuffix(filepath.Base(relPath), filepath.Ext(relPath))
+
+	if base == "index" {
+		return "/" + dir
+	}
+	return "/" + dir + base + "/"
+}
+
+// errorOverlayHTML renders a developer-facing page describing a build
+// failure, inspired by Hugo's in-browser error overlay.
+func errorOverlayHTML(buildErr BuildError) []byte {
+	location := buildErr.Path
+	if buildErr.Line > 0 {
+		location = fmt.Sprintf("%s:%d", buildErr.Path, buildErr.Line)
+	}
+
+	var snippet string
+	if buildErr.Snippet != "" {
+		snippet = fmt.Sprintf("<pre>%s</pre>", html.EscapeString(buildErr.Snippet))
+	}
+
+	return []byte(fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head><title>goss: build error</title></head>
+<body style="font-family: monospace; background: #1e1e1e; color: #f0f0f0; padding: 2em;">
+<h1 style="color: #ff6b6b;">Build Error</h1>
+<p><strong>%s</strong></p>
+<p>%s</p>
+%s
+%s
+</body>
+</html>`, html.EscapeString(location), html.EscapeString(buildErr.Message), snippet, liveReloadScript))
+}
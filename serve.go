@@ -0,0 +1,533 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gorilla/websocket"
+)
+
+// debounceWindow coalesces bursts of filesystem events (e.g. an editor's
+// save-then-rename) into a single rebuild.
+const debounceWindow = 100 * time.Millisecond
+
+// devServer holds the state a running dev server needs across rebuilds:
+// the last page model (for incremental rebuilds and regenerating
+// sitemap.xml/feed.xml) and the live-reload websocket hub.
+type devServer struct {
+	config     Configuration
+	siteConfig SiteConfig
+
+	mu          sync.Mutex
+	pages       []Page
+	buildErrors map[string]BuildError // keyed by Page.URL
+	assets      AssetManifest
+
+	hub *liveReloadHub
+}
+
+// serve starts a development server, watches the input and templates
+// directories with fsnotify, and live-reloads connected browsers after
+// each rebuild.
+func serve(config Configuration) {
+	// Dev server always serves drafts, since they're exactly what an
+	// author is previewing; loadPage logs a "Draft:" line for each one
+	// so that's visible without digging through front matter.
+	config.IncludeDrafts = true
+
+	siteConfig, err := loadSiteConfig(config.ConfigPath)
+	if err != nil {
+		fmt.Printf("%s Error loading %s: %s\n", red("Error:"), config.ConfigPath, err)
+	}
+
+	server := &devServer{
+		config:      config,
+		siteConfig:  siteConfig,
+		hub:         newLiveReloadHub(),
+		buildErrors: make(map[string]BuildError),
+	}
+	pages, buildErrors, assets := build(config)
+	server.pages = pages
+	server.assets = assets
+	for _, buildErr := range buildErrors {
+		server.buildErrors[buildErr.URL] = buildErr
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		fmt.Printf("%s Error creating file watcher: %s\n", red("Error:"), err)
+		return
+	}
+	defer watcher.Close()
+
+	if err := addWatchRecursive(watcher, config.InputDir); err != nil {
+		fmt.Printf("%s Error watching %s: %s\n", red("Error:"), config.InputDir, err)
+	}
+	if err := addWatchRecursive(watcher, config.TemplatesDir); err != nil {
+		fmt.Printf("%s Error watching %s: %s\n", red("Error:"), config.TemplatesDir, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/__goss/livereload", http.HandlerFunc(server.hub.serveWS))
+	mux.Handle("/", server.errorOverlayMiddleware(liveReloadMiddleware(http.FileServer(http.Dir(config.OutputDir)))))
+
+	serverAddr := fmt.Sprintf("%s:%d", config.Host, config.Port)
+	fmt.Printf("\n%s http://%s\n", green("Starting server at"), serverAddr)
+	fmt.Printf("%s\n", yellow("Press Ctrl+C to quit"))
+	fmt.Printf("%s\n", blue("Watching for changes in:"))
+	fmt.Printf("%s %s\n", blue("- Input:"), config.InputDir)
+	fmt.Printf("%s %s\n", blue("- Templates:"), config.TemplatesDir)
+
+	httpServer := &http.Server{Addr: serverAddr, Handler: logRequest(mux)}
+	go func() {
+		if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("%s Server error: %s\n", red("Error:"), err)
+			os.Exit(1)
+		}
+	}()
+
+	// Create channel for clean termination, signaled on Ctrl+C/SIGTERM.
+	done := make(chan bool)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sigCh
+		fmt.Printf("\n%s\n", yellow("Shutting down..."))
+		close(done)
+	}()
+
+	go server.watch(watcher, done)
+
+	<-done // Block until a shutdown signal arrives
+	httpServer.Close()
+}
+
+// addWatchRecursive adds dir and every directory beneath it to watcher.
+// fsnotify only watches a single directory level, so new subdirectories
+// are picked up as fsnotify.Create events arrive (see devServer.watch).
+func addWatchRecursive(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && !strings.HasPrefix(filepath.Base(path), ".") {
+			return watcher.Add(path)
+		}
+		return nil
+	})
+}
+
+// watch reacts to fsnotify events, debouncing bursts before triggering an
+// incremental rebuild, until done is closed.
+func (s *devServer) watch(watcher *fsnotify.Watcher, done chan bool) {
+	pending := make(map[string]struct{})
+	var pendingMu sync.Mutex
+	var timer *time.Timer
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if strings.HasPrefix(filepath.Base(event.Name), ".") || strings.HasSuffix(event.Name, ".tmp") {
+				continue
+			}
+
+			// Track newly created directories so the watcher covers them too.
+			if event.Op&fsnotify.Create != 0 {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					addWatchRecursive(watcher, event.Name)
+				}
+			}
+
+			pendingMu.Lock()
+			pending[event.Name] = struct{}{}
+			pendingMu.Unlock()
+
+			if timer == nil {
+				// The debounce callback runs in its own goroutine, so it
+				// must snapshot-and-clear pending under pendingMu rather
+				// than handing flush the shared map: otherwise a fresh
+				// event landing as the timer fires races with flush's
+				// reads/deletes on the same map.
+				timer = time.AfterFunc(debounceWindow, func() {
+					pendingMu.Lock()
+					changed := pending
+					pending = make(map[string]struct{})
+					pendingMu.Unlock()
+					s.flush(changed)
+				})
+			} else {
+				timer.Reset(debounceWindow)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			fmt.Printf("%s File watcher error: %s\n", red("Error:"), err)
+		case <-done:
+			return
+		}
+	}
+}
+
+// flush rebuilds whatever changed since the last debounce window and
+// notifies connected browsers to reload. changed is a snapshot owned
+// solely by the caller; flush doesn't need to synchronize access to it.
+func (s *devServer) flush(changedPaths map[string]struct{}) {
+	changed := make([]string, 0, len(changedPaths))
+	for path := range changedPaths {
+		changed = append(changed, path)
+	}
+
+	rebuiltAnyPage := false
+	for _, path := range changed {
+		switch {
+		case strings.HasPrefix(path, s.config.TemplatesDir):
+			fmt.Printf("\n%s %s\n", yellow("Template changed:"), path)
+			relPath, _ := filepath.Rel(s.config.TemplatesDir, path)
+			s.rebuildPagesUsingTemplate(relPath)
+			rebuiltAnyPage = true
+		case strings.HasPrefix(path, s.config.InputDir):
+			fmt.Printf("\n%s %s\n", yellow("Input changed:"), path)
+			if isMarkdownFile(path) {
+				s.rebuildPage(path)
+				rebuiltAnyPage = true
+			} else if s.rebuildAsset(path) {
+				// The asset's content hash changed, so every page that
+				// linked to its old hashed URL via {{ asset }} needs to
+				// be re-rendered to pick up the new one.
+				fmt.Printf("%s %s\n", yellow("Asset hash changed, re-rendering all pages:"), path)
+				s.rebuildAllPages()
+				rebuiltAnyPage = true
+			}
+		}
+	}
+
+	if rebuiltAnyPage {
+		s.mu.Lock()
+		if err := generateSitemap(s.pages, s.config); err != nil {
+			fmt.Printf("%s Error generating sitemap.xml: %s\n", red("Error:"), err)
+		}
+		if err := generateAtomFeed(s.pages, s.config); err != nil {
+			fmt.Printf("%s Error generating feed.xml: %s\n", red("Error:"), err)
+		}
+		if s.siteConfig.Highlight.CSSClasses {
+			if err := writeChromaCSS(s.siteConfig.Highlight, s.config.OutputDir); err != nil {
+				fmt.Printf("%s Error writing chroma.css: %s\n", red("Error:"), err)
+			}
+		}
+		site := buildSite(s.pages, s.siteConfig)
+		if err := generateListPages(site, s.config, s.assets); err != nil {
+			fmt.Printf("%s Error generating list pages: %s\n", red("Error:"), err)
+		}
+		s.mu.Unlock()
+	}
+
+	fmt.Printf("%s\n", green("Rebuild complete!"))
+	s.hub.broadcast("reload")
+}
+
+// rebuildPage re-renders a single markdown file, swaps it into the
+// in-memory page model, and records or clears its build error. If the
+// file itself has been deleted or renamed away, the page is dropped from
+// the model and its stale output file is removed instead.
+func (s *devServer) rebuildPage(inputPath string) {
+	s.mu.Lock()
+	existingPages := append([]Page(nil), s.pages...)
+	assets := maps.Clone(s.assets) // snapshot: rendering reads this outside the lock
+	s.mu.Unlock()
+
+	url := derivePageURL(inputPath, s.config.InputDir)
+
+	if _, err := os.Stat(inputPath); os.IsNotExist(err) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		delete(s.buildErrors, url)
+		for i, p := range s.pages {
+			if p.InputPath == inputPath {
+				s.pages = append(s.pages[:i], s.pages[i+1:]...)
+				break
+			}
+		}
+
+		relPath, err := filepath.Rel(s.config.InputDir, inputPath)
+		if err == nil {
+			outputPath := derivePageOutputPath(filepath.Join(s.config.OutputDir, relPath), inputPath)
+			if err := os.Remove(outputPath); err != nil && !os.IsNotExist(err) {
+				fmt.Printf("%s Error removing stale output for %s: %s\n", red("Error:"), inputPath, err)
+			}
+		}
+		return
+	}
+
+	page, buildErr := renderSinglePage(inputPath, existingPages, s.siteConfig, s.config, assets)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if buildErr != nil {
+		s.buildErrors[url] = *buildErr
+	} else {
+		delete(s.buildErrors, url)
+	}
+
+	if page != nil && !isPublished(*page, s.config) {
+		// Front matter changed to unpublish this page (e.g. a future
+		// date); drop it from the in-memory model.
+		for i, p := range s.pages {
+			if p.InputPath == inputPath {
+				s.pages = append(s.pages[:i], s.pages[i+1:]...)
+				break
+			}
+		}
+		return
+	}
+
+	if page == nil {
+		return
+	}
+	for i, p := range s.pages {
+		if p.InputPath == inputPath {
+			s.pages[i] = *page
+			return
+		}
+	}
+	s.pages = append(s.pages, *page)
+}
+
+// rebuildPagesUsingTemplate re-renders every page whose front matter
+// references templateRelPath (or default.html, for pages with none set).
+func (s *devServer) rebuildPagesUsingTemplate(templateRelPath string) {
+	s.mu.Lock()
+	var affected []string
+	for _, p := range s.pages {
+		tmpl := p.FrontMatter.Template
+		if tmpl == "" {
+			tmpl = "default.html"
+		}
+		if tmpl == templateRelPath {
+			affected = append(affected, p.InputPath)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, inputPath := range affected {
+		s.rebuildPage(inputPath)
+	}
+}
+
+// rebuildAsset re-fingerprints and re-copies a single non-markdown input
+// file, updating its entry in the asset manifest, garbage-collecting the
+// superseded hashed file, and reporting whether the content hash (and
+// therefore every page's {{ asset }} link to it) actually changed.
+func (s *devServer) rebuildAsset(inputPath string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	relPath, err := filepath.Rel(s.config.InputDir, inputPath)
+	if err != nil {
+		fmt.Printf("%s Error resolving %s: %s\n", red("Error:"), inputPath, err)
+		return false
+	}
+	relPath = filepath.ToSlash(relPath)
+	oldInfo, hadOld := s.assets[relPath]
+
+	if err := copyAssetFingerprinted(inputPath, s.config.InputDir, s.config.OutputDir, s.assets); err != nil {
+		fmt.Printf("%s Error copying %s: %s\n", red("Error:"), inputPath, err)
+		return false
+	}
+
+	newInfo := s.assets[relPath]
+	if !hadOld || oldInfo.URL == newInfo.URL {
+		return false
+	}
+
+	oldOutputPath := filepath.Join(s.config.OutputDir, filepath.FromSlash(strings.TrimPrefix(oldInfo.URL, "/")))
+	if err := os.Remove(oldOutputPath); err != nil && !os.IsNotExist(err) {
+		fmt.Printf("%s Error removing superseded asset %s: %s\n", red("Error:"), oldOutputPath, err)
+	}
+	return true
+}
+
+// rebuildAllPages re-renders every page currently in the site model, e.g.
+// after a changed asset's content hash invalidated every {{ asset }} link
+// that may reference it.
+func (s *devServer) rebuildAllPages() {
+	s.mu.Lock()
+	inputPaths := make([]string, 0, len(s.pages))
+	for _, p := range s.pages {
+		inputPaths = append(inputPaths, p.InputPath)
+	}
+	s.mu.Unlock()
+
+	for _, inputPath := range inputPaths {
+		s.rebuildPage(inputPath)
+	}
+}
+
+// liveReloadHub tracks connected browsers and pushes reload notifications
+// to them over websocket.
+type liveReloadHub struct {
+	upgrader websocket.Upgrader
+
+	mu      sync.Mutex
+	clients map[*websocket.Conn]struct{}
+}
+
+func newLiveReloadHub() *liveReloadHub {
+	return &liveReloadHub{
+		upgrader: websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }},
+		clients:  make(map[*websocket.Conn]struct{}),
+	}
+}
+
+func (h *liveReloadHub) serveWS(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		fmt.Printf("%s Error upgrading livereload connection: %s\n", red("Error:"), err)
+		return
+	}
+
+	h.mu.Lock()
+	h.clients[conn] = struct{}{}
+	h.mu.Unlock()
+
+	// The browser never sends anything; just wait for it to disconnect.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			break
+		}
+	}
+
+	h.mu.Lock()
+	delete(h.clients, conn)
+	h.mu.Unlock()
+	conn.Close()
+}
+
+func (h *liveReloadHub) broadcast(message string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for conn := range h.clients {
+		conn.WriteMessage(websocket.TextMessage, []byte(message))
+	}
+}
+
+// liveReloadScript connects back to the livereload endpoint and refreshes
+// the page whenever the dev server pushes a "reload" message.
+const liveReloadScript = `<script>
+(function() {
+	var proto = window.location.protocol === "https:" ? "wss:" : "ws:";
+	var socket = new WebSocket(proto + "//" + window.location.host + "/__goss/livereload");
+	socket.onmessage = function(event) {
+		if (event.data === "reload") {
+			window.location.reload();
+		}
+	};
+})();
+</script>
+`
+
+// errorOverlayMiddleware serves the build error overlay for any route
+// whose page currently has a build error, instead of whatever (possibly
+// stale or missing) file sits on disk. Disabled by --disable-browser-error.
+func (s *devServer) errorOverlayMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.config.DisableBrowserError {
+			s.mu.Lock()
+			buildErr, hasErr := s.buildErrors[r.URL.Path]
+			s.mu.Unlock()
+
+			if hasErr {
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write(errorOverlayHTML(buildErr))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// liveReloadMiddleware buffers the response and, for HTML pages, injects
+// liveReloadScript just before </body>.
+func liveReloadMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bw := &bufferingResponseWriter{header: make(http.Header), statusCode: http.StatusOK}
+		next.ServeHTTP(bw, r)
+
+		body := bw.buf.Bytes()
+		if strings.Contains(bw.header.Get("Content-Type"), "text/html") {
+			body = injectBeforeBodyClose(body, liveReloadScript)
+		}
+
+		for k, v := range bw.header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("Content-Length", fmt.Sprintf("%d", len(body)))
+		w.WriteHeader(bw.statusCode)
+		w.Write(body)
+	})
+}
+
+// injectBeforeBodyClose inserts snippet immediately before the last
+// </body>, or appends it if the document has none.
+func injectBeforeBodyClose(body []byte, snippet string) []byte {
+	idx := bytes.LastIndex(body, []byte("</body>"))
+	if idx == -1 {
+		return append(body, []byte(snippet)...)
+	}
+
+	out := make([]byte, 0, len(body)+len(snippet))
+	out = append(out, body[:idx]...)
+	out = append(out, []byte(snippet)...)
+	out = append(out, body[idx:]...)
+	return out
+}
+
+// bufferingResponseWriter captures a handler's response so middleware can
+// inspect and rewrite it before it reaches the client.
+type bufferingResponseWriter struct {
+	header     http.Header
+	statusCode int
+	buf        bytes.Buffer
+}
+
+func (w *bufferingResponseWriter) Header() http.Header { return w.header }
+
+func (w *bufferingResponseWriter) WriteHeader(statusCode int) { w.statusCode = statusCode }
+
+func (w *bufferingResponseWriter) Write(b []byte) (int, error) { return w.buf.Write(b) }
+
+// logRequest wraps an http.Handler with request logging
+func logRequest(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		// Call the original handler
+		handler.ServeHTTP(w, r)
+
+		// Log the request with colors
+		fmt.Printf("%s %s %s %s %s\n",
+			cyan(r.RemoteAddr),
+			time.Now().Format("02/Jan/2006:15:04:05 -0700"),
+			magenta(r.URL.Path),
+			r.Method,
+			yellow(time.Since(start).String()),
+		)
+	})
+}
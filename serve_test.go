@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestInjectBeforeBodyClose(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "inserts before closing body tag",
+			body: "<html><body><p>hi</p></body></html>",
+			want: "<html><body><p>hi</p>SNIPPET</body></html>",
+		},
+		{
+			name: "uses the last closing body tag when there are several",
+			body: "<body>one</body><body>two</body>",
+			want: "<body>one</body><body>twoSNIPPET</body>",
+		},
+		{
+			name: "appends when there is no closing body tag",
+			body: "<html><p>no body tag</p></html>",
+			want: "<html><p>no body tag</p></html>SNIPPET",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := injectBeforeBodyClose([]byte(tt.body), "SNIPPET")
+			if string(got) != tt.want {
+				t.Errorf("injectBeforeBodyClose(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// sitemapURLSet and sitemapURL model the sitemaps.org schema.
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc        string `xml:"loc"`
+	LastMod    string `xml:"lastmod,omitempty"`
+	Changefreq string `xml:"changefreq,omitempty"`
+	Priority   string `xml:"priority,omitempty"`
+}
+
+// generateSitemap writes sitemap.xml from the pages rendered during this
+// build. Pages opt out with `sitemap: false` in their front matter, and may
+// set `priority`/`changefreq` to influence how crawlers weigh them.
+func generateSitemap(pages []Page, config Configuration) error {
+	if config.BaseURL == "" {
+		fmt.Printf("%s BaseURL not set, skipping sitemap.xml\n", yellow("Warning:"))
+		return nil
+	}
+
+	urlSet := sitemapURLSet{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+
+	for _, page := range pages {
+		if page.FrontMatter.Sitemap != nil && !*page.FrontMatter.Sitemap {
+			continue
+		}
+
+		entry := sitemapURL{
+			Loc:        config.BaseURL + page.URL,
+			Changefreq: page.FrontMatter.Changefreq,
+			Priority:   page.FrontMatter.Priority,
+		}
+		if !page.FrontMatter.Date.IsZero() {
+			entry.LastMod = page.FrontMatter.Date.Format("2006-01-02")
+		}
+
+		urlSet.URLs = append(urlSet.URLs, entry)
+	}
+
+	out, err := xml.MarshalIndent(urlSet, "", "  ")
+	if err != nil {
+		return err
+	}
+	out = append([]byte(xml.Header), out...)
+
+	return os.WriteFile(filepath.Join(config.OutputDir, "sitemap.xml"), out, 0o644)
+}